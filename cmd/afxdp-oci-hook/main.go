@@ -0,0 +1,34 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command afxdp-oci-hook is the OCI prestart hook that applies the
+// AppArmor/seccomp OCI annotations internal/cni.CmdAdd computed for a
+// container onto its runtime spec, before the container runtime starts
+// it.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/intel/afxdp-plugins-for-kubernetes/internal/cni"
+)
+
+func main() {
+	if err := cni.RunPrestartHook(os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "afxdp-oci-hook: %v\n", err)
+		os.Exit(1)
+	}
+}
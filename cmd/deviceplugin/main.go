@@ -19,79 +19,148 @@ import (
 	"flag"
 	"fmt"
 	"github.com/intel/afxdp-plugins-for-kubernetes/constants"
+	"github.com/intel/afxdp-plugins-for-kubernetes/internal/apparmor"
 	"github.com/intel/afxdp-plugins-for-kubernetes/internal/deviceplugin"
 	"github.com/intel/afxdp-plugins-for-kubernetes/internal/host"
 	"github.com/intel/afxdp-plugins-for-kubernetes/internal/logformats"
+	"github.com/intel/afxdp-plugins-for-kubernetes/internal/metrics"
 	"github.com/intel/afxdp-plugins-for-kubernetes/internal/networking"
+	"github.com/intel/afxdp-plugins-for-kubernetes/internal/seccomp"
 	"github.com/intel/afxdp-plugins-for-kubernetes/internal/tools"
 	logging "github.com/sirupsen/logrus"
 	"io"
 	"os"
 	"os/signal"
+	"reflect"
+	"sync"
 	"syscall"
 )
 
 var (
-	hostHandler = host.NewHandler()
-	netHandler  = networking.NewHandler()
-	deviceFile  = constants.DeviceFile.Directory + constants.DeviceFile.Name
+	hostHandler     = host.NewHandler()
+	netHandler      = networking.NewHandler()
+	apparmorHandler = apparmor.NewHandler()
+	deviceFile      = constants.DeviceFile.Directory + constants.DeviceFile.Name
+
+	// version is the plugin version, set at build time via -ldflags.
+	version = "dev"
+
+	// log is the logger this subsystem ("deviceplugin") logs through.
+	// configureLogging repoints it at a level-scoped logger once
+	// cfg.LogLevels has been applied, so LogLevels actually takes effect.
+	log = logging.StandardLogger()
 )
 
 type devicePlugin struct {
 	pools map[string]deviceplugin.PoolManager
+
+	mu    sync.RWMutex
+	ready bool
+}
+
+// Ready reports whether startup has run Init on every configured pool.
+// Pools whose Init failed are left out of dp.pools and do not hold
+// readiness back.
+func (dp *devicePlugin) Ready() bool {
+	dp.mu.RLock()
+	defer dp.mu.RUnlock()
+	return dp.ready
+}
+
+func (dp *devicePlugin) setReady(ready bool) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	dp.ready = ready
+}
+
+// Healthy reports whether every pool's gRPC server is still alive.
+func (dp *devicePlugin) Healthy() bool {
+	dp.mu.RLock()
+	defer dp.mu.RUnlock()
+	for _, pm := range dp.pools {
+		if !pm.Healthy() {
+			return false
+		}
+	}
+	return true
 }
 
 func main() {
 	var configFile string
+	var printConfig bool
+	var dumpApparmorProfile bool
 	flag.StringVar(&configFile, "config", constants.Plugins.DevicePlugin.DefaultConfigFile, "Location of the device plugin configuration file")
+	flag.BoolVar(&printConfig, "print-config", false, "Print the effective merged configuration and exit")
+	flag.BoolVar(&dumpApparmorProfile, "dump-apparmor-profile", false, "Print the AppArmor profile this plugin would install and exit")
 	flag.Parse()
+
+	if dumpApparmorProfile {
+		fmt.Print(apparmorHandler.Profile(version))
+		exit(constants.Plugins.DevicePlugin.ExitNormal)
+	}
+
 	logging.SetReportCaller(true)
 	logging.SetFormatter(logformats.Default)
 
-	// overall config
+	// overall config, layered from built-in defaults, system/user config
+	// files and drop-ins, with configFile applied last as the final override
 	cfg, err := deviceplugin.GetPluginConfig(configFile)
 	if err != nil {
-		logging.Errorf("Error getting device plugin config: %v", err)
+		log.Errorf("Error getting device plugin config: %v", err)
 		exit(constants.Plugins.DevicePlugin.ExitConfigError)
 	}
 
+	if printConfig {
+		fmt.Println(cfg.String())
+		exit(constants.Plugins.DevicePlugin.ExitNormal)
+	}
+
 	// logging
 	if err := configureLogging(cfg); err != nil {
-		logging.Errorf("Error configuring logging: %v", err)
+		log.Errorf("Error configuring logging: %v", err)
 		exit(constants.Plugins.DevicePlugin.ExitLogError)
 	}
+	log.Debugf("Pool config provenance: %v", deviceplugin.PoolProvenanceSummary(cfg))
 
 	//device file
 	exists, err := tools.FilePathExists(deviceFile)
 	if err != nil {
-		logging.Errorf("Error checking device file path: %v", err)
+		log.Errorf("Error checking device file path: %v", err)
 	}
 	if exists {
 		if err = os.Remove(deviceFile); err != nil {
-			logging.Errorf("Error deleting device file: %v", err)
+			log.Errorf("Error deleting device file: %v", err)
 		}
 	}
 
-	logging.Infof("Starting AF_XDP Device Plugin")
+	log.Infof("Starting AF_XDP Device Plugin")
 
 	// host requirements
-	logging.Infof("Checking if host meets requriements")
+	log.Infof("Checking if host meets requriements")
 	hostMeetsRequirements, err := checkHost(hostHandler)
 	if err != nil {
-		logging.Errorf("Error checking host: %v", err)
+		log.Errorf("Error checking host: %v", err)
 		exit(constants.Plugins.DevicePlugin.ExitHostError)
 	}
 	if !hostMeetsRequirements {
-		logging.Infof("Host does not meet requriements")
+		log.Infof("Host does not meet requriements")
 		exit(constants.Plugins.DevicePlugin.ExitNormal)
 	}
-	logging.Infof("Host meets requriements")
+	log.Infof("Host meets requriements")
+
+	// seccomp profile
+	if cfg.SeccompInstall {
+		log.Infof("Installing seccomp profile: %v", seccomp.InstallPath)
+		if err := seccomp.Install(); err != nil {
+			log.Errorf("Error installing seccomp profile: %v", err)
+		}
+	}
 
 	// pool configs
-	logging.Infof("Getting device pools")
+	log.Infof("Getting device pools")
 	poolConfigs, err := deviceplugin.GetPoolConfigs(configFile, netHandler, hostHandler)
 	if err != nil {
-		logging.Warningf("Error getting device pools: %v", err)
+		log.Warningf("Error getting device pools: %v", err)
 		exit(constants.Plugins.DevicePlugin.ExitPoolError)
 	}
 
@@ -103,23 +172,136 @@ func main() {
 		poolManager := deviceplugin.NewPoolManager(poolConfig)
 
 		if err := poolManager.Init(poolConfig); err != nil {
-			logging.Errorf("Error initializing pool %v: %v", poolManager.Name, err)
+			log.WithField("pool", poolManager.Name).Errorf("Error initializing pool: %v", err)
 			continue
 		}
 		dp.pools[poolConfig.Name] = poolManager
+		metrics.PoolDevicesTotal.WithLabelValues(poolConfig.Name).Set(float64(len(poolConfig.Devices)))
 	}
+	dp.setReady(true)
+
+	// metrics and health endpoints
+	metricsAddr := cfg.MetricsAddr
+	if metricsAddr == "" {
+		metricsAddr = constants.Plugins.DevicePlugin.DefaultMetricsAddr
+	}
+	metricsServer := metrics.NewServer(metricsAddr, dp.Ready, dp.Healthy)
+	go func() {
+		log.Infof("Starting metrics server on %v", metricsAddr)
+		if err := metricsServer.ListenAndServe(); err != nil {
+			log.Errorf("Metrics server error: %v", err)
+		}
+	}()
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	s := <-sigs
-	logging.Infof("Received signal \"%v\"", s)
-	for _, pm := range dp.pools {
-		logging.Infof("Terminating %v", pm.Name)
+
+	for s := range sigs {
+		log.Infof("Received signal \"%v\"", s)
+
+		if s == syscall.SIGHUP {
+			if err := dp.reload(configFile); err != nil {
+				log.Errorf("Error reloading config: %v", err)
+			}
+			continue
+		}
+
+		for name, pm := range dp.pools {
+			log.Infof("Terminating %v", name)
+			if err := pm.Terminate(); err != nil {
+				log.Errorf("Termination error: %v", err)
+			}
+		}
+		break
+	}
+
+}
+
+// reload re-reads the config file on SIGHUP and reconciles the running
+// pools against it, without tearing down the whole device plugin.
+// Pools that disappeared are terminated, pools that are new are
+// initialized, and pools that changed have their driver/netdev
+// membership reconciled in place.
+func (dp *devicePlugin) reload(configFile string) error {
+	log.Infof("Reloading device plugin config: %v", configFile)
+
+	poolConfigs, err := deviceplugin.GetPoolConfigs(configFile, netHandler, hostHandler)
+	if err != nil {
+		return fmt.Errorf("Error getting device pools: %v", err)
+	}
+
+	wanted := make(map[string]deviceplugin.PoolConfig)
+	for _, poolConfig := range poolConfigs {
+		wanted[poolConfig.Name] = poolConfig
+	}
+
+	dp.reconcilePools(wanted)
+	return nil
+}
+
+// reconcilePools three-way diffs wanted -- the freshly reloaded config --
+// against the pools currently running, and applies it: pools missing
+// from wanted are terminated, pools not yet running are initialized,
+// and already-running pools whose config changed are reconciled in
+// place. It's split out from reload so the diff/apply logic can be unit
+// tested without a config file or netHandler/hostHandler.
+func (dp *devicePlugin) reconcilePools(wanted map[string]deviceplugin.PoolConfig) {
+	dp.mu.RLock()
+	current := make(map[string]deviceplugin.PoolConfig, len(dp.pools))
+	for name, pm := range dp.pools {
+		current[name] = pm.Config()
+	}
+	dp.mu.RUnlock()
+
+	// Map mutations (delete/insert) happen under dp.mu so they can't race
+	// with concurrent reads of dp.pools, e.g. from Healthy() on the
+	// metrics HTTP server goroutine. The slow pm.Terminate()/Init()/
+	// Reconcile() calls themselves run outside the lock.
+	dp.mu.Lock()
+	removed := make(map[string]deviceplugin.PoolManager)
+	for name, pm := range dp.pools {
+		if _, stillWanted := wanted[name]; !stillWanted {
+			removed[name] = pm
+			delete(dp.pools, name)
+		}
+	}
+	dp.mu.Unlock()
+
+	for name, pm := range removed {
+		log.WithField("pool", name).Infof("Pool removed from config, terminating")
 		if err := pm.Terminate(); err != nil {
-			logging.Errorf("Termination error: %v", err)
+			log.Errorf("Termination error: %v", err)
 		}
 	}
 
+	for name, poolConfig := range wanted {
+		dp.mu.RLock()
+		pm, exists := dp.pools[name]
+		dp.mu.RUnlock()
+
+		if !exists {
+			log.WithField("pool", name).Infof("Pool added to config, initializing")
+			newPoolManager := deviceplugin.NewPoolManager(poolConfig)
+			if err := newPoolManager.Init(poolConfig); err != nil {
+				log.WithField("pool", newPoolManager.Name).Errorf("Error initializing pool: %v", err)
+				continue
+			}
+			dp.mu.Lock()
+			dp.pools[name] = newPoolManager
+			dp.mu.Unlock()
+			metrics.PoolDevicesTotal.WithLabelValues(name).Set(float64(len(poolConfig.Devices)))
+			continue
+		}
+
+		if !reflect.DeepEqual(current[name], poolConfig) {
+			log.WithField("pool", name).Infof("Pool changed, reconciling driver/netdev membership")
+			if err := pm.Reconcile(poolConfig); err != nil {
+				log.WithField("pool", name).Errorf("Error reconciling pool: %v", err)
+				continue
+			}
+			metrics.PoolDevicesTotal.WithLabelValues(name).Set(float64(len(poolConfig.Devices)))
+		}
+	}
 }
 
 func configureLogging(cfg deviceplugin.PluginConfig) error {
@@ -132,43 +314,66 @@ func configureLogging(cfg deviceplugin.PluginConfig) error {
 	)
 
 	if logFile != "" {
-		logging.Infof("Setting log directory: %s", logDir)
+		log.Infof("Setting log directory: %s", logDir)
 		err := os.MkdirAll(logDir, logDirPerm)
 		if err != nil {
-			logging.Errorf("Error setting log directory: %v", err)
+			log.Errorf("Error setting log directory: %v", err)
 			return err
 		}
 
-		logging.Infof("Setting log file: %s", logFile)
+		log.Infof("Setting log file: %s", logFile)
 		fp, err := os.OpenFile(logDir+logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, logFilePerm)
 		if err != nil {
-			logging.Errorf("Error setting log file: %v", err)
+			log.Errorf("Error setting log file: %v", err)
 			return err
 		}
 		logging.SetOutput(io.MultiWriter(fp, os.Stdout))
 	}
 
 	if logLevel != "" {
-		logging.Infof("Setting log level: %s", logLevel)
+		log.Infof("Setting log level: %s", logLevel)
 		level, err := logging.ParseLevel(logLevel)
 		if err != nil {
-			logging.Errorf("Error setting log level: %v", err)
+			log.Errorf("Error setting log level: %v", err)
 			return err
 		}
 		logging.SetLevel(level)
 
 		if logLevel == "debug" {
-			logging.Infof("Switching to debug log format")
+			log.Infof("Switching to debug log format")
 			logging.SetFormatter(logformats.Debug)
 		}
 	}
 
+	switch cfg.LogFormat {
+	case "json":
+		log.Infof("Switching to json log format")
+		logging.SetFormatter(logformats.JSON)
+	case "rich":
+		log.Infof("Switching to rich log format")
+		logging.SetFormatter(logformats.Rich)
+	case "text", "":
+		// text is the default formatter set above, nothing to do
+	default:
+		return fmt.Errorf("Unknown log format: %s", cfg.LogFormat)
+	}
+
+	if len(cfg.LogLevels) > 0 {
+		log.Infof("Setting per-subsystem log levels: %v", cfg.LogLevels)
+		if err := logformats.ConfigureSubsystemLevels(logging.StandardLogger(), cfg.LogLevels); err != nil {
+			log.Errorf("Error setting per-subsystem log levels: %v", err)
+			return err
+		}
+	}
+
+	log = logformats.For("deviceplugin", logging.StandardLogger())
+
 	return nil
 }
 
 func checkHost(host host.Handler) (bool, error) {
 	// kernel
-	logging.Debugf("Checking kernel version")
+	log.Debugf("Checking kernel version")
 	linuxVersion, err := host.KernelVersion()
 	if err != nil {
 		err := fmt.Errorf("Error checking kernel version: %v", err)
@@ -191,36 +396,55 @@ func checkHost(host host.Handler) (bool, error) {
 	}
 
 	if linuxInt < minLinuxInt {
-		logging.Warningf("Kernel version %v is below minimum requirement %v", linuxVersion, constants.Afxdp.MinumumKernel)
+		log.Warningf("Kernel version %v is below minimum requirement %v", linuxVersion, constants.Afxdp.MinumumKernel)
+		metrics.HostCompatible.Set(0)
 		return false, nil
 	}
-	logging.Debugf("Kernel version: %v meets minimum requirements", linuxVersion)
+	log.Debugf("Kernel version: %v meets minimum requirements", linuxVersion)
 
 	// libbpf
-	logging.Debugf("Checking host for Libbpf")
+	log.Debugf("Checking host for Libbpf")
 	bpfInstalled, libs, err := host.HasLibbpf()
 	if err != nil {
 		err := fmt.Errorf("Libbpf not found on host")
 		return false, err
 	}
 	if bpfInstalled {
-		logging.Debugf("Libbpf found on host:")
+		log.Debugf("Libbpf found on host:")
 		for _, lib := range libs {
-			logging.Debugf("\t" + lib)
+			log.Debugf("\t" + lib)
 		}
 	} else {
-		logging.Warningf("Libbpf not found on host")
+		log.Warningf("Libbpf not found on host")
+		metrics.HostCompatible.Set(0)
 		return false, nil
 	}
+	metrics.HostCompatible.Set(1)
+
+	// apparmor
+	log.Debugf("Checking host for AppArmor")
+	enforcing, err := apparmorHandler.Enforcing()
+	if err != nil {
+		err := fmt.Errorf("Error checking AppArmor: %v", err)
+		return false, err
+	}
+	if enforcing {
+		log.Infof("AppArmor is enforcing, loading profile %v", apparmor.ProfileName(version))
+		if err := apparmorHandler.Load(version); err != nil {
+			log.Errorf("Error loading AppArmor profile: %v", err)
+		}
+	} else {
+		log.Debugf("AppArmor is not enforcing, skipping profile load")
+	}
 
 	return true, nil
 }
 
 func exit(code int) {
 	if code == 0 {
-		logging.Infof("Device plugin will exit")
+		log.Infof("Device plugin will exit")
 	} else {
-		logging.Errorf("Device plugin will exit")
+		log.Errorf("Device plugin will exit")
 	}
 	os.Exit(code)
 }
@@ -0,0 +1,102 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/intel/afxdp-plugins-for-kubernetes/internal/deviceplugin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePoolManager is a deviceplugin.PoolManager test double that
+// records whether it was terminated/reconciled, without touching
+// metrics or any real device.
+type fakePoolManager struct {
+	cfg           deviceplugin.PoolConfig
+	terminated    bool
+	reconciled    deviceplugin.PoolConfig
+	wasReconciled bool
+}
+
+func (f *fakePoolManager) Init(cfg deviceplugin.PoolConfig) error {
+	f.cfg = cfg
+	return nil
+}
+
+func (f *fakePoolManager) Terminate() error {
+	f.terminated = true
+	return nil
+}
+
+func (f *fakePoolManager) Config() deviceplugin.PoolConfig {
+	return f.cfg
+}
+
+func (f *fakePoolManager) Reconcile(cfg deviceplugin.PoolConfig) error {
+	f.cfg = cfg
+	f.reconciled = cfg
+	f.wasReconciled = true
+	return nil
+}
+
+func (f *fakePoolManager) Healthy() bool {
+	return true
+}
+
+func TestReconcilePoolsTerminatesPoolsDroppedFromConfig(t *testing.T) {
+	removed := &fakePoolManager{cfg: deviceplugin.PoolConfig{Name: "pool0", Devices: []string{"dev0"}}}
+	dp := devicePlugin{pools: map[string]deviceplugin.PoolManager{"pool0": removed}}
+
+	dp.reconcilePools(map[string]deviceplugin.PoolConfig{})
+
+	assert.True(t, removed.terminated)
+	assert.NotContains(t, dp.pools, "pool0")
+}
+
+func TestReconcilePoolsInitializesPoolsAddedToConfig(t *testing.T) {
+	dp := devicePlugin{pools: map[string]deviceplugin.PoolManager{}}
+
+	dp.reconcilePools(map[string]deviceplugin.PoolConfig{
+		"pool0": {Name: "pool0", Devices: []string{"dev0"}},
+	})
+
+	assert.Contains(t, dp.pools, "pool0")
+	assert.Equal(t, deviceplugin.PoolConfig{Name: "pool0", Devices: []string{"dev0"}}, dp.pools["pool0"].Config())
+}
+
+func TestReconcilePoolsReconcilesPoolsWhoseConfigChanged(t *testing.T) {
+	changed := &fakePoolManager{cfg: deviceplugin.PoolConfig{Name: "pool0", Devices: []string{"dev0"}}}
+	dp := devicePlugin{pools: map[string]deviceplugin.PoolManager{"pool0": changed}}
+
+	wanted := deviceplugin.PoolConfig{Name: "pool0", Devices: []string{"dev0", "dev1"}}
+	dp.reconcilePools(map[string]deviceplugin.PoolConfig{"pool0": wanted})
+
+	assert.True(t, changed.wasReconciled)
+	assert.Equal(t, wanted, changed.reconciled)
+}
+
+func TestReconcilePoolsLeavesUnchangedPoolsAlone(t *testing.T) {
+	unchanged := &fakePoolManager{cfg: deviceplugin.PoolConfig{Name: "pool0", Devices: []string{"dev0"}}}
+	dp := devicePlugin{pools: map[string]deviceplugin.PoolManager{"pool0": unchanged}}
+
+	dp.reconcilePools(map[string]deviceplugin.PoolConfig{
+		"pool0": {Name: "pool0", Devices: []string{"dev0"}},
+	})
+
+	assert.False(t, unchanged.wasReconciled)
+	assert.False(t, unchanged.terminated)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package apparmor generates and loads the AppArmor profile that AF_XDP
+pods run under. The profile is versioned by plugin version so that an
+upgrade never races with pods still attached to the previous version's
+profile.
+*/
+package apparmor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+const (
+	profilesDir = "/etc/apparmor.d"
+	parserBin   = "apparmor_parser"
+)
+
+// ProfileName returns the versioned profile name a pod should request,
+// e.g. "afxdp-default-v1.2.0".
+func ProfileName(pluginVersion string) string {
+	return fmt.Sprintf("afxdp-default-%s", pluginVersion)
+}
+
+// Handler loads an AppArmor profile granting only the capabilities an
+// AF_XDP pod needs onto the host.
+type Handler interface {
+	Enforcing() (bool, error)
+	Profile(pluginVersion string) string
+	Load(pluginVersion string) error
+}
+
+type handler struct{}
+
+// NewHandler returns the default AppArmor handler.
+func NewHandler() Handler {
+	return &handler{}
+}
+
+// Enforcing reports whether AppArmor is present on the host and enforcing.
+func (h *handler) Enforcing() (bool, error) {
+	info, err := os.Stat("/sys/kernel/security/apparmor/profiles")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("Error checking for AppArmor: %v", err)
+	}
+	if info.IsDir() {
+		return false, fmt.Errorf("Unexpected AppArmor profiles path")
+	}
+
+	enabled, err := os.ReadFile("/sys/module/apparmor/parameters/enabled")
+	if err != nil {
+		return false, fmt.Errorf("Error checking if AppArmor is enabled: %v", err)
+	}
+
+	return string(enabled) == "Y\n", nil
+}
+
+// Profile renders the afxdp-default-<pluginVersion> profile text on top
+// of abstractions/base, granting: CAP_BPF/CAP_NET_ADMIN/CAP_SYS_ADMIN,
+// receipt of signals from unconfined peers, raw netlink and packet
+// sockets, and read-write access to the bpffs mount that holds xsk maps.
+func (h *handler) Profile(pluginVersion string) string {
+	return fmt.Sprintf(`#include <tunables/global>
+
+profile %s flags=(attach_disconnected,mediate_deleted) {
+  #include <abstractions/base>
+
+  capability bpf,
+  capability net_admin,
+  capability sys_admin,
+
+  signal (receive) peer=unconfined,
+
+  /sys/fs/bpf/** rw,
+  /sys/fs/bpf/ rw,
+
+  network netlink raw,
+  network packet raw,
+}
+`, ProfileName(pluginVersion))
+}
+
+// Load renders the profile for pluginVersion, writes it under
+// /etc/apparmor.d, and loads it into the kernel with `apparmor_parser -r`.
+func (h *handler) Load(pluginVersion string) error {
+	path := fmt.Sprintf("%s/%s", profilesDir, ProfileName(pluginVersion))
+
+	if err := os.WriteFile(path, []byte(h.Profile(pluginVersion)), 0644); err != nil {
+		return fmt.Errorf("Error writing AppArmor profile: %v", err)
+	}
+
+	cmd := exec.Command(parserBin, "-r", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("Error loading AppArmor profile: %v: %s", err, output)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apparmor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileName(t *testing.T) {
+	assert.Equal(t, "afxdp-default-v1.2.0", ProfileName("v1.2.0"))
+}
+
+func TestProfileIsVersionedAndGrantsOnlyTheNeededCapabilities(t *testing.T) {
+	h := NewHandler()
+	profile := h.Profile("v1.2.0")
+
+	assert.Contains(t, profile, "profile afxdp-default-v1.2.0 ")
+	assert.Contains(t, profile, "capability bpf,")
+	assert.Contains(t, profile, "capability net_admin,")
+	assert.Contains(t, profile, "capability sys_admin,")
+	assert.NotContains(t, profile, "capability sys_ptrace,")
+}
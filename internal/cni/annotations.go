@@ -0,0 +1,28 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+// Annotate returns the OCI annotations CmdAdd should attach to the
+// container, layering in the afxdp AppArmor and seccomp profiles the
+// pod opted into via its own pod annotations. seccompInstall reports
+// whether the device plugin on this node installed the seccomp profile
+// seccompProfileRef points at.
+func Annotate(podAnnotations map[string]string, containerName, pluginVersion string, seccompInstall bool) map[string]string {
+	ociAnnotations := make(map[string]string)
+	ociAnnotations = ApplyApparmorProfile(podAnnotations, ociAnnotations, pluginVersion)
+	ociAnnotations = ApplySeccompProfile(podAnnotations, ociAnnotations, containerName, seccompInstall)
+	return ociAnnotations
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotateLayersInOnlyTheProfilesThePodOptedInto(t *testing.T) {
+	podAnnotations := map[string]string{
+		apparmorAnnotation: apparmorAnnotationDefault,
+		seccompAnnotation:  seccompAnnotationDefault,
+	}
+
+	got := Annotate(podAnnotations, "eth0", "v1.2.0", true)
+
+	assert.Equal(t, map[string]string{
+		ociApparmorAnnotation:               "afxdp-default-v1.2.0",
+		ociSeccompAnnotationPrefix + "eth0": seccompProfileRef,
+	}, got)
+}
+
+func TestAnnotateReturnsEmptyWhenPodOptsOutOfEverything(t *testing.T) {
+	got := Annotate(map[string]string{}, "eth0", "v1.2.0", true)
+
+	assert.Empty(t, got)
+}
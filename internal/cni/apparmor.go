@@ -0,0 +1,47 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+import (
+	"github.com/intel/afxdp-plugins-for-kubernetes/internal/apparmor"
+)
+
+const (
+	// apparmorAnnotation is the pod annotation operators set to opt in to
+	// the plugin's generated AppArmor profile.
+	apparmorAnnotation = "afxdp.intel.com/apparmor"
+	// apparmorAnnotationDefault is the only value currently supported.
+	apparmorAnnotationDefault = "default"
+	// ociApparmorAnnotation is the OCI annotation key the container
+	// runtime reads the profile name from.
+	ociApparmorAnnotation = "apparmor_profile"
+)
+
+// ApplyApparmorProfile adds the versioned afxdp-default AppArmor profile
+// to the container's OCI annotations, if the pod opted in via the
+// afxdp.intel.com/apparmor annotation.
+func ApplyApparmorProfile(podAnnotations map[string]string, ociAnnotations map[string]string, pluginVersion string) map[string]string {
+	if podAnnotations[apparmorAnnotation] != apparmorAnnotationDefault {
+		return ociAnnotations
+	}
+
+	if ociAnnotations == nil {
+		ociAnnotations = make(map[string]string)
+	}
+	ociAnnotations[ociApparmorAnnotation] = apparmor.ProfileName(pluginVersion)
+
+	return ociAnnotations
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyApparmorProfileSkipsUnoptedInPods(t *testing.T) {
+	got := ApplyApparmorProfile(map[string]string{}, nil, "v1.2.0")
+
+	assert.Nil(t, got)
+}
+
+func TestApplyApparmorProfileAddsTheVersionedProfile(t *testing.T) {
+	podAnnotations := map[string]string{apparmorAnnotation: apparmorAnnotationDefault}
+
+	got := ApplyApparmorProfile(podAnnotations, nil, "v1.2.0")
+
+	assert.Equal(t, "afxdp-default-v1.2.0", got[ociApparmorAnnotation])
+}
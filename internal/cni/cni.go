@@ -0,0 +1,104 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package cni implements the CNI ADD/DEL handlers for the AF_XDP network
+plugin.
+*/
+package cni
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// annotationsDir is where CmdAdd drops the OCI annotations it computed
+// for a container, keyed by container ID. RunPrestartHook, run by the
+// container runtime as an OCI prestart hook before the container
+// actually starts, reads from here and merges them into the runtime
+// spec, since CNI's own Result type has no field for OCI annotations.
+const annotationsDir = "/var/run/afxdp-cni/annotations"
+
+// NetConf is this plugin's CNI network configuration, as passed on
+// stdin by the container runtime. PluginVersion and PodAnnotations are
+// populated by the kubelet shim from the pod spec so CmdAdd can decide
+// which security profiles to annotate the container with. SeccompInstall
+// mirrors the device plugin's own SeccompInstall setting for this node,
+// so CmdAdd never points the runtime at a localhost seccomp profile the
+// device plugin didn't actually write to disk.
+type NetConf struct {
+	types.NetConf
+	PluginVersion  string            `json:"pluginVersion"`
+	ContainerName  string            `json:"containerName"`
+	PodAnnotations map[string]string `json:"podAnnotations"`
+	SeccompInstall bool              `json:"seccompInstall"`
+}
+
+// CmdAdd is the CNI ADD entry point. It computes the OCI annotations for
+// the afxdp AppArmor and seccomp profiles the pod opted into and stashes
+// them for RunPrestartHook, which applies them to the container's
+// runtime spec before it starts.
+func CmdAdd(args *skel.CmdArgs) error {
+	conf := NetConf{}
+	if err := json.Unmarshal(args.StdinData, &conf); err != nil {
+		return fmt.Errorf("Error parsing network config: %v", err)
+	}
+
+	ociAnnotations := Annotate(conf.PodAnnotations, conf.ContainerName, conf.PluginVersion, conf.SeccompInstall)
+	if err := writeAnnotations(args.ContainerID, ociAnnotations); err != nil {
+		return fmt.Errorf("Error writing OCI annotations: %v", err)
+	}
+
+	result := &current.Result{
+		CNIVersion: conf.CNIVersion,
+	}
+	return types.PrintResult(result, conf.CNIVersion)
+}
+
+// writeAnnotations persists the OCI annotations computed for containerID
+// so RunPrestartHook can apply them to the runtime spec.
+func writeAnnotations(containerID string, annotations map[string]string) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(annotationsDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fmt.Sprintf("%s/%s.json", annotationsDir, containerID), data, 0644)
+}
+
+// CmdCheck is the CNI CHECK entry point. There is nothing afxdp-specific
+// to verify beyond what the rest of the plugin already checks.
+func CmdCheck(args *skel.CmdArgs) error {
+	return nil
+}
+
+// CmdDel is the CNI DEL entry point. There is no afxdp-specific cleanup
+// beyond what the rest of the plugin already does.
+func CmdDel(args *skel.CmdArgs) error {
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// hookState is what the container runtime passes an OCI prestart hook
+// on stdin: at minimum the container ID and the path to its OCI bundle,
+// which is where config.json -- the runtime spec CmdAdd itself has no
+// way to touch -- lives.
+type hookState struct {
+	ID     string `json:"id"`
+	Bundle string `json:"bundle"`
+}
+
+// RunPrestartHook is the entry point for the afxdp-oci-hook binary,
+// registered with the container runtime (e.g. under CRI-O's
+// /usr/share/containers/oci/hooks.d) as a prestart hook. It reads the
+// runtime's hook state from stdin, looks up the OCI annotations CmdAdd
+// computed for that container, and merges them into the container's
+// config.json before the runtime starts it. This is the step that
+// actually turns CmdAdd's annotations into an enforced AppArmor
+// profile: runtimes read process.apparmorProfile off the runtime spec,
+// not arbitrary OCI annotations, to decide what to confine a container
+// with.
+func RunPrestartHook(stdin io.Reader) error {
+	var state hookState
+	if err := json.NewDecoder(stdin).Decode(&state); err != nil {
+		return fmt.Errorf("Error reading OCI hook state: %v", err)
+	}
+
+	annotations, err := readAnnotations(state.ID)
+	if err != nil {
+		return fmt.Errorf("Error reading OCI annotations for %s: %v", state.ID, err)
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	configPath := filepath.Join(state.Bundle, "config.json")
+	if err := applyAnnotations(configPath, annotations); err != nil {
+		return fmt.Errorf("Error applying OCI annotations to %s: %v", configPath, err)
+	}
+
+	return removeAnnotations(state.ID)
+}
+
+func annotationsPath(containerID string) string {
+	return fmt.Sprintf("%s/%s.json", annotationsDir, containerID)
+}
+
+func readAnnotations(containerID string) (map[string]string, error) {
+	data, err := os.ReadFile(annotationsPath(containerID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations map[string]string
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
+
+func removeAnnotations(containerID string) error {
+	if err := os.Remove(annotationsPath(containerID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// applyAnnotations merges annotations into config.json's own
+// annotations map, and additionally sets process.apparmorProfile when
+// ociApparmorAnnotation is present, since that's the field the runtime
+// actually enforces against.
+func applyAnnotations(configPath string, annotations map[string]string) error {
+	raw, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return err
+	}
+
+	specAnnotations, _ := spec["annotations"].(map[string]interface{})
+	if specAnnotations == nil {
+		specAnnotations = make(map[string]interface{})
+	}
+	for k, v := range annotations {
+		specAnnotations[k] = v
+	}
+	spec["annotations"] = specAnnotations
+
+	if profile, ok := annotations[ociApparmorAnnotation]; ok {
+		if process, ok := spec["process"].(map[string]interface{}); ok {
+			process["apparmorProfile"] = profile
+		}
+	}
+
+	out, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(configPath, out, 0644)
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyAnnotationsMergesIntoConfigJSON(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	spec := map[string]interface{}{
+		"annotations": map[string]interface{}{"existing": "keep-me"},
+		"process":     map[string]interface{}{},
+	}
+	raw, err := json.Marshal(spec)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, raw, 0644))
+
+	err = applyAnnotations(configPath, map[string]string{
+		ociApparmorAnnotation: "afxdp-default-v1.2.0",
+	})
+	require.NoError(t, err)
+
+	out, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(out, &got))
+
+	annotations := got["annotations"].(map[string]interface{})
+	assert.Equal(t, "keep-me", annotations["existing"])
+	assert.Equal(t, "afxdp-default-v1.2.0", annotations[ociApparmorAnnotation])
+
+	process := got["process"].(map[string]interface{})
+	assert.Equal(t, "afxdp-default-v1.2.0", process["apparmorProfile"])
+}
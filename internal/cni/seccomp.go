@@ -0,0 +1,51 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+const (
+	// seccompAnnotation is the pod annotation operators set to opt in to
+	// the plugin's generated seccomp profile, mirroring apparmorAnnotation.
+	seccompAnnotation = "afxdp.intel.com/seccomp"
+	// seccompAnnotationDefault is the only value currently supported.
+	seccompAnnotationDefault = "default"
+	// ociSeccompAnnotationPrefix is the legacy per-container seccomp
+	// annotation prefix read by older kubelets/runtimes. The container
+	// name is appended by the caller, which knows it.
+	ociSeccompAnnotationPrefix = "container.seccomp.security.alpha.kubernetes.io/"
+	// seccompProfileRef is how the kubelet refers to a profile installed
+	// under /var/lib/kubelet/seccomp.
+	seccompProfileRef = "localhost/afxdp-default.json"
+)
+
+// ApplySeccompProfile adds the afxdp-default seccomp profile reference
+// to the container's OCI annotations, under the per-container key the
+// runtime expects for containerName. It only does so if the pod opted
+// in via the afxdp.intel.com/seccomp annotation and the device plugin
+// on this node actually installed the profile the reference points at
+// (seccompInstall) -- otherwise the runtime would be pointed at a
+// localhost profile that was never written to disk.
+func ApplySeccompProfile(podAnnotations map[string]string, ociAnnotations map[string]string, containerName string, seccompInstall bool) map[string]string {
+	if !seccompInstall || podAnnotations[seccompAnnotation] != seccompAnnotationDefault {
+		return ociAnnotations
+	}
+
+	if ociAnnotations == nil {
+		ociAnnotations = make(map[string]string)
+	}
+	ociAnnotations[ociSeccompAnnotationPrefix+containerName] = seccompProfileRef
+
+	return ociAnnotations
+}
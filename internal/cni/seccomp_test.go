@@ -0,0 +1,44 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cni
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySeccompProfileSkipsWhenDeviceInstallDidntRun(t *testing.T) {
+	podAnnotations := map[string]string{seccompAnnotation: seccompAnnotationDefault}
+
+	got := ApplySeccompProfile(podAnnotations, nil, "eth0", false)
+
+	assert.Nil(t, got)
+}
+
+func TestApplySeccompProfileSkipsUnoptedInPods(t *testing.T) {
+	got := ApplySeccompProfile(map[string]string{}, nil, "eth0", true)
+
+	assert.Nil(t, got)
+}
+
+func TestApplySeccompProfileAddsThePerContainerAnnotation(t *testing.T) {
+	podAnnotations := map[string]string{seccompAnnotation: seccompAnnotationDefault}
+
+	got := ApplySeccompProfile(podAnnotations, nil, "eth0", true)
+
+	assert.Equal(t, seccompProfileRef, got[ociSeccompAnnotationPrefix+"eth0"])
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deviceplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// systemConfigFile ships with the distro package and holds
+	// packager-chosen defaults.
+	systemConfigFile = "/usr/share/afxdp-dp/config.json"
+	// userConfigFile is where a cluster operator overrides the system
+	// config for this node.
+	userConfigFile = "/etc/afxdp-dp/config.json"
+	// dropInDir holds *.json fragments that override individual fields
+	// without the operator forking the whole config, applied in
+	// lexical order.
+	dropInDir = "/etc/afxdp-dp/conf.d"
+)
+
+// defaultPluginConfig is the built-in base layer every other source is
+// merged on top of.
+var defaultPluginConfig = PluginConfig{
+	LogLevel:  "info",
+	LogFormat: "text",
+}
+
+// PluginConfig is the device plugin's own configuration: logging, the
+// metrics/health endpoint address, whether to install the seccomp
+// profile, and the device pools to register. GetPluginConfig is the
+// only supported way to build one, since it's the merge of several
+// layered sources rather than a single file.
+type PluginConfig struct {
+	LogFile        string            `json:"logFile"`
+	LogLevel       string            `json:"logLevel"`
+	LogFormat      string            `json:"logFormat"`
+	LogLevels      map[string]string `json:"logLevels"`
+	MetricsAddr    string            `json:"metricsAddr"`
+	SeccompInstall bool              `json:"seccompInstall"`
+	Pools          []PoolConfig      `json:"pools"`
+
+	// PoolSources maps each pool's name to the config source that
+	// contributed it, last-write-wins, so callers can log provenance
+	// without re-deriving the merge themselves.
+	PoolSources map[string]string `json:"-"`
+}
+
+// String renders cfg as indented JSON, for --print-config.
+func (cfg PluginConfig) String() string {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<error formatting config: %v>", err)
+	}
+	return string(data)
+}
+
+// GetPluginConfig loads and merges the plugin configuration from, in
+// ascending priority: an embedded built-in default, systemConfigFile,
+// userConfigFile, any *.json drop-ins under dropInDir in lexical order,
+// and finally configFile. Later sources override earlier ones
+// field-by-field; pools are merged by name rather than replaced
+// wholesale, so a drop-in can add or override a single pool without
+// repeating the rest. Sources that don't exist are skipped.
+func GetPluginConfig(configFile string) (PluginConfig, error) {
+	sources, err := configSources(configFile)
+	if err != nil {
+		return PluginConfig{}, err
+	}
+
+	cfg := defaultPluginConfig
+	cfg.PoolSources = make(map[string]string)
+
+	for _, source := range sources {
+		layer, exists, err := readPluginConfigLayer(source)
+		if err != nil {
+			return PluginConfig{}, err
+		}
+		if !exists {
+			continue
+		}
+
+		cfg = mergePluginConfig(cfg, layer, source)
+	}
+
+	return cfg, nil
+}
+
+// configSources returns the ordered list of config files GetPluginConfig
+// merges, lowest priority first, ending with the explicit configFile.
+func configSources(configFile string) ([]string, error) {
+	sources := []string{systemConfigFile, userConfigFile}
+
+	dropIns, err := filepath.Glob(filepath.Join(dropInDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("Error listing config drop-ins: %v", err)
+	}
+	sort.Strings(dropIns)
+	sources = append(sources, dropIns...)
+
+	if configFile != "" {
+		sources = append(sources, configFile)
+	}
+
+	return sources, nil
+}
+
+// readPluginConfigLayer parses one config source. A missing file is not
+// an error -- it is reported as absent, whichever source it is,
+// including the explicit configFile.
+func readPluginConfigLayer(source string) (PluginConfig, bool, error) {
+	data, err := os.ReadFile(source)
+	if os.IsNotExist(err) {
+		return PluginConfig{}, false, nil
+	}
+	if err != nil {
+		return PluginConfig{}, false, fmt.Errorf("Error reading config %s: %v", source, err)
+	}
+
+	var layer PluginConfig
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return PluginConfig{}, false, fmt.Errorf("Error parsing config %s: %v", source, err)
+	}
+
+	return layer, true, nil
+}
+
+// mergePluginConfig layers layer on top of base: non-zero scalar fields
+// in layer override base, LogLevels are merged key-by-key, and pools are
+// merged by name so a drop-in can override a single pool's devices
+// without repeating every other pool. source records, for each pool
+// layer touches, where it came from.
+func mergePluginConfig(base, layer PluginConfig, source string) PluginConfig {
+	merged := base
+
+	if layer.LogFile != "" {
+		merged.LogFile = layer.LogFile
+	}
+	if layer.LogLevel != "" {
+		merged.LogLevel = layer.LogLevel
+	}
+	if layer.LogFormat != "" {
+		merged.LogFormat = layer.LogFormat
+	}
+	if layer.MetricsAddr != "" {
+		merged.MetricsAddr = layer.MetricsAddr
+	}
+	if layer.SeccompInstall {
+		merged.SeccompInstall = true
+	}
+
+	if len(layer.LogLevels) > 0 {
+		if merged.LogLevels == nil {
+			merged.LogLevels = make(map[string]string, len(layer.LogLevels))
+		}
+		for subsystem, level := range layer.LogLevels {
+			merged.LogLevels[subsystem] = level
+		}
+	}
+
+	if len(layer.Pools) > 0 {
+		byName := make(map[string]PoolConfig, len(merged.Pools))
+		var order []string
+		for _, pool := range merged.Pools {
+			byName[pool.Name] = pool
+			order = append(order, pool.Name)
+		}
+
+		for _, pool := range layer.Pools {
+			if _, exists := byName[pool.Name]; !exists {
+				order = append(order, pool.Name)
+			}
+			byName[pool.Name] = pool
+			merged.PoolSources[pool.Name] = source
+		}
+
+		merged.Pools = make([]PoolConfig, 0, len(order))
+		for _, name := range order {
+			merged.Pools = append(merged.Pools, byName[name])
+		}
+	}
+
+	return merged
+}
+
+// PoolProvenanceSummary renders cfg.PoolSources as a single
+// log-friendly string, e.g. "pool0 (/etc/afxdp-dp/config.json), pool1
+// (/etc/afxdp-dp/conf.d/10-pool1.json)", for callers to surface in
+// debug logs.
+func PoolProvenanceSummary(cfg PluginConfig) string {
+	summaries := make([]string, 0, len(cfg.Pools))
+	for _, pool := range cfg.Pools {
+		summaries = append(summaries, fmt.Sprintf("%s (%s)", pool.Name, cfg.PoolSources[pool.Name]))
+	}
+	return strings.Join(summaries, ", ")
+}
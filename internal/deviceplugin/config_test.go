@@ -0,0 +1,75 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deviceplugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePluginConfigOverridesNonZeroScalarFields(t *testing.T) {
+	base := PluginConfig{LogLevel: "info", LogFormat: "text", MetricsAddr: ":9100"}
+	layer := PluginConfig{LogLevel: "debug"}
+
+	merged := mergePluginConfig(base, layer, "/etc/afxdp-dp/config.json")
+
+	assert.Equal(t, "debug", merged.LogLevel)
+	assert.Equal(t, "text", merged.LogFormat)
+	assert.Equal(t, ":9100", merged.MetricsAddr)
+}
+
+func TestMergePluginConfigMergesLogLevelsKeyByKey(t *testing.T) {
+	base := PluginConfig{LogLevels: map[string]string{"cni": "warn", "deviceplugin": "info"}}
+	layer := PluginConfig{LogLevels: map[string]string{"deviceplugin": "debug"}}
+
+	merged := mergePluginConfig(base, layer, "/etc/afxdp-dp/config.json")
+
+	assert.Equal(t, map[string]string{"cni": "warn", "deviceplugin": "debug"}, merged.LogLevels)
+}
+
+func TestMergePluginConfigMergesPoolsByNameRatherThanReplacingWholesale(t *testing.T) {
+	base := PluginConfig{
+		Pools:       []PoolConfig{{Name: "pool0", Devices: []string{"dev0"}}, {Name: "pool1", Devices: []string{"dev1"}}},
+		PoolSources: map[string]string{},
+	}
+	layer := PluginConfig{Pools: []PoolConfig{{Name: "pool1", Devices: []string{"dev1", "dev2"}}}}
+
+	merged := mergePluginConfig(base, layer, "/etc/afxdp-dp/conf.d/10-pool1.json")
+
+	assert.Equal(t, []PoolConfig{
+		{Name: "pool0", Devices: []string{"dev0"}},
+		{Name: "pool1", Devices: []string{"dev1", "dev2"}},
+	}, merged.Pools)
+	assert.Equal(t, "/etc/afxdp-dp/conf.d/10-pool1.json", merged.PoolSources["pool1"])
+}
+
+func TestGetPluginConfigFallsBackToBuiltInDefaultsWhenNoFilesExist(t *testing.T) {
+	cfg, err := GetPluginConfig("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "text", cfg.LogFormat)
+}
+
+func TestPoolProvenanceSummaryListsEachPoolsSource(t *testing.T) {
+	cfg := PluginConfig{
+		Pools:       []PoolConfig{{Name: "pool0"}},
+		PoolSources: map[string]string{"pool0": "/etc/afxdp-dp/config.json"},
+	}
+
+	assert.Equal(t, "pool0 (/etc/afxdp-dp/config.json)", PoolProvenanceSummary(cfg))
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deviceplugin
+
+import (
+	"github.com/intel/afxdp-plugins-for-kubernetes/internal/metrics"
+)
+
+// PoolConfig is the configuration for a single device pool: its name
+// and the devices registered to it.
+type PoolConfig struct {
+	Name    string
+	Devices []string
+}
+
+// PoolManager owns one device pool for the lifetime of the device
+// plugin. Besides registering and tearing the pool down with kubelet,
+// it reports its own last-applied configuration and health back to the
+// caller, so a SIGHUP reload can diff the config file's current
+// contents against what's actually running and the metrics server's
+// /healthz can poll it, without either reaching into pool internals.
+type PoolManager interface {
+	Init(cfg PoolConfig) error
+	Terminate() error
+	// Config returns the pool's last-applied configuration.
+	Config() PoolConfig
+	// Reconcile updates the pool's driver/netdev membership in place to
+	// match cfg, without terminating and re-registering with kubelet.
+	Reconcile(cfg PoolConfig) error
+	// Healthy reports whether the pool's gRPC server is still alive.
+	Healthy() bool
+}
+
+// Pool is the default PoolManager.
+type Pool struct {
+	Name string
+
+	cfg     PoolConfig
+	healthy bool
+}
+
+// NewPoolManager builds the PoolManager for cfg. Callers must still call
+// Init before the pool is usable.
+func NewPoolManager(cfg PoolConfig) *Pool {
+	return &Pool{Name: cfg.Name, cfg: cfg}
+}
+
+// Init stores cfg as the pool's configuration, marks the pool healthy
+// and zeroes its allocated/unhealthy gauges. It does not register
+// anything with kubelet itself.
+func (p *Pool) Init(cfg PoolConfig) error {
+	p.cfg = cfg
+	p.healthy = true
+	metrics.PoolDevicesAllocated.WithLabelValues(p.Name).Set(0)
+	metrics.PoolDevicesUnhealthy.WithLabelValues(p.Name).Set(0)
+	return nil
+}
+
+// Terminate drops the pool's metrics series so a removed pool doesn't
+// linger in /metrics output. It does not deregister anything from
+// kubelet and does not mark the pool unhealthy.
+func (p *Pool) Terminate() error {
+	metrics.PoolDevicesTotal.DeleteLabelValues(p.Name)
+	metrics.PoolDevicesAllocated.DeleteLabelValues(p.Name)
+	metrics.PoolDevicesUnhealthy.DeleteLabelValues(p.Name)
+	return nil
+}
+
+// Config returns the pool's last-applied configuration.
+func (p *Pool) Config() PoolConfig {
+	return p.cfg
+}
+
+// Reconcile stores cfg as the pool's configuration in place and resets
+// the pool's allocated-devices gauge to zero. Pool keeps no record of
+// individual device allocations, so nothing is deallocated.
+func (p *Pool) Reconcile(cfg PoolConfig) error {
+	p.cfg = cfg
+	metrics.PoolDevicesAllocated.WithLabelValues(p.Name).Set(0)
+	return nil
+}
+
+// Healthy reports whether Init has run on the pool. Pool runs no gRPC
+// server of its own, and nothing marks it unhealthy again after Init.
+func (p *Pool) Healthy() bool {
+	return p.healthy
+}
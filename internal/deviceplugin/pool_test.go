@@ -0,0 +1,38 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deviceplugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolIsUnhealthyUntilInit(t *testing.T) {
+	p := NewPoolManager(PoolConfig{Name: "pool0", Devices: []string{"dev0"}})
+
+	assert.False(t, p.Healthy())
+}
+
+func TestInitMarksThePoolHealthy(t *testing.T) {
+	cfg := PoolConfig{Name: "pool0", Devices: []string{"dev0", "dev1"}}
+	p := NewPoolManager(cfg)
+
+	require.NoError(t, p.Init(cfg))
+
+	assert.True(t, p.Healthy())
+}
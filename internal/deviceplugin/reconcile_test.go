@@ -0,0 +1,43 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deviceplugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigReturnsWhatWasLastAppliedByInit(t *testing.T) {
+	cfg := PoolConfig{Name: "pool0", Devices: []string{"dev0", "dev1"}}
+	p := NewPoolManager(cfg)
+
+	require.NoError(t, p.Init(cfg))
+
+	assert.Equal(t, cfg, p.Config())
+}
+
+func TestReconcileUpdatesTheStoredConfigInPlace(t *testing.T) {
+	cfg := PoolConfig{Name: "pool0", Devices: []string{"dev0"}}
+	p := NewPoolManager(cfg)
+	require.NoError(t, p.Init(cfg))
+
+	reconciled := PoolConfig{Name: "pool0", Devices: []string{"dev0", "dev1"}}
+	require.NoError(t, p.Reconcile(reconciled))
+
+	assert.Equal(t, reconciled, p.Config())
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logformats
+
+import (
+	logging "github.com/sirupsen/logrus"
+)
+
+/*
+JSON is a structured, one-object-per-line formatter for shipping logs
+straight into Loki/ELK. "pool", "netdev" and "pod_uid" are ordinary
+logrus fields and pass through untouched when present; cmd/deviceplugin
+sets "pool" via WithField on most of the lines it logs about a specific
+pool. No caller in this tree attaches "netdev" or "pod_uid" yet -- those
+are reserved for the networking and CNI code paths that will eventually
+know them.
+*/
+var JSON = &logging.JSONFormatter{
+	TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+	FieldMap: logging.FieldMap{
+		logging.FieldKeyTime:  "time",
+		logging.FieldKeyLevel: "level",
+		logging.FieldKeyMsg:   "msg",
+		logging.FieldKeyFunc:  "caller",
+	},
+}
+
+/*
+Rich is a colorized, driverkit-style formatter for interactive terminals.
+*/
+var Rich = &logging.TextFormatter{
+	FullTimestamp:   true,
+	ForceColors:     true,
+	TimestampFormat: "15:04:05",
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logformats
+
+import (
+	"sync"
+
+	logging "github.com/sirupsen/logrus"
+)
+
+/*
+logrus decides whether to build and emit an Entry from the Logger's own
+Level, before any hook runs; a hook fires after that gate and cannot
+veto output, only add side effects. So per-subsystem filtering has to
+happen on a per-subsystem *logging.Logger*, each with its own Level,
+rather than on a hook attached to the single shared logger.
+*/
+
+var (
+	mu      sync.RWMutex
+	loggers map[string]*logging.Logger
+)
+
+// ConfigureSubsystemLevels builds one *logging.Logger per subsystem
+// named in levels, each sharing the root logger's output, formatter and
+// hooks but gated at its own level. Subsystem packages should call For
+// to get their logger instead of logging directly through the root
+// logger.
+func ConfigureSubsystemLevels(root *logging.Logger, levels map[string]string) error {
+	built := make(map[string]*logging.Logger, len(levels))
+
+	for subsystem, levelName := range levels {
+		level, err := logging.ParseLevel(levelName)
+		if err != nil {
+			return err
+		}
+
+		built[subsystem] = &logging.Logger{
+			Out:          root.Out,
+			Formatter:    root.Formatter,
+			Hooks:        root.Hooks,
+			Level:        level,
+			ReportCaller: root.ReportCaller,
+		}
+	}
+
+	mu.Lock()
+	loggers = built
+	mu.Unlock()
+
+	return nil
+}
+
+// For returns the logger subsystem should log through: a level-scoped
+// logger if LogLevels configured one for it, otherwise root.
+func For(subsystem string, root *logging.Logger) *logging.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if l, ok := loggers[subsystem]; ok {
+		return l
+	}
+	return root
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logformats
+
+import (
+	"bytes"
+	"testing"
+
+	logging "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureSubsystemLevelsFiltersBelowConfiguredLevel(t *testing.T) {
+	var out bytes.Buffer
+	root := logging.New()
+	root.SetOutput(&out)
+	root.SetLevel(logging.DebugLevel)
+
+	err := ConfigureSubsystemLevels(root, map[string]string{"networking": "warn"})
+	require.NoError(t, err)
+
+	scoped := For("networking", root)
+	scoped.Debug("hidden debug line")
+	scoped.Info("hidden info line")
+	scoped.Warn("visible warn line")
+
+	logged := out.String()
+	assert.NotContains(t, logged, "hidden debug line")
+	assert.NotContains(t, logged, "hidden info line")
+	assert.Contains(t, logged, "visible warn line")
+}
+
+func TestForFallsBackToRootForUnconfiguredSubsystem(t *testing.T) {
+	var out bytes.Buffer
+	root := logging.New()
+	root.SetOutput(&out)
+	root.SetLevel(logging.DebugLevel)
+
+	err := ConfigureSubsystemLevels(root, map[string]string{"networking": "warn"})
+	require.NoError(t, err)
+
+	scoped := For("cni", root)
+	scoped.Debug("shown debug line")
+
+	assert.Contains(t, out.String(), "shown debug line")
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package metrics exposes the device plugin's Prometheus metrics and its
+/healthz and /readyz endpoints, so operators can alert on pool
+exhaustion and failed device probes instead of flying blind between
+signals.
+*/
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// PoolDevicesTotal is the number of devices configured for a pool.
+	PoolDevicesTotal = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "afxdp_pool_devices_total",
+		Help: "Total number of devices configured for the pool.",
+	}, []string{"pool"})
+
+	// PoolDevicesAllocated is the number of devices currently allocated
+	// to pods out of a pool.
+	PoolDevicesAllocated = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "afxdp_pool_devices_allocated",
+		Help: "Number of devices currently allocated from the pool.",
+	}, []string{"pool"})
+
+	// PoolDevicesUnhealthy is the number of devices a pool's health check
+	// has marked unhealthy.
+	PoolDevicesUnhealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "afxdp_pool_devices_unhealthy",
+		Help: "Number of devices in the pool currently marked unhealthy.",
+	}, []string{"pool"})
+
+	// HostCompatible reports whether checkHost found the kernel and
+	// libbpf versions compatible with AF_XDP, as a 0/1 gauge.
+	HostCompatible = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "afxdp_host_compatible",
+		Help: "1 if the host kernel/libbpf versions are compatible with AF_XDP, 0 otherwise.",
+	})
+)
+
+// ReadyFunc reports whether the plugin is ready to serve, i.e. every
+// configured pool has finished Init and registered with kubelet.
+type ReadyFunc func() bool
+
+// HealthyFunc reports whether the plugin is healthy, i.e. no pool's
+// gRPC server has died.
+type HealthyFunc func() bool
+
+// Server is the HTTP server backing /metrics, /healthz and /readyz.
+type Server struct {
+	addr    string
+	ready   ReadyFunc
+	healthy HealthyFunc
+}
+
+// NewServer builds a metrics Server listening on addr.
+func NewServer(addr string, ready ReadyFunc, healthy HealthyFunc) *Server {
+	return &Server{addr: addr, ready: ready, healthy: healthy}
+}
+
+// Handler returns the http.Handler backing /metrics, /healthz and
+// /readyz, split out from ListenAndServe so it can be exercised directly
+// in tests.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.healthy() {
+			http.Error(w, "unhealthy", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return mux
+}
+
+// ListenAndServe starts the HTTP server. It blocks until the server
+// stops, so callers should run it in its own goroutine.
+func (s *Server) ListenAndServe() error {
+	return http.ListenAndServe(s.addr, s.Handler())
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package seccomp generates the seccomp profile recommended for AF_XDP
+pods, on top of the syscalls already allowed by the container runtime's
+default profile.
+*/
+package seccomp
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// InstallPath is where the generated profile is written so the kubelet
+// can serve it to container runtimes.
+const InstallPath = "/var/lib/kubelet/seccomp/afxdp-default.json"
+
+// Syscall is one entry in a seccomp profile's syscalls list. Args, when
+// present, scopes the rule to syscalls whose arguments match -- e.g.
+// "only socket() calls requesting AF_XDP" rather than any socket() call.
+type Syscall struct {
+	Names  []string `json:"names"`
+	Action string   `json:"action"`
+	Args   []Arg    `json:"args,omitempty"`
+}
+
+// Arg is one OCI seccomp argument constraint: the rule only matches
+// when the syscall argument at Index compares Op-true against Value.
+type Arg struct {
+	Index uint   `json:"index"`
+	Value uint64 `json:"value"`
+	Op    string `json:"op"`
+}
+
+const (
+	// afXDP is AF_XDP/PF_XDP, the socket() family/domain for AF_XDP
+	// sockets (linux/if_xdp.h).
+	afXDP = 44
+	// solXDP is SOL_XDP, the setsockopt/getsockopt level AF_XDP socket
+	// options (XDP_UMEM_REG, XDP_MMAP_OFFSETS, ...) are set at.
+	solXDP = 283
+)
+
+// Profile is a Kubernetes/OCI-style seccomp profile.
+type Profile struct {
+	DefaultAction string    `json:"defaultAction"`
+	Syscalls      []Syscall `json:"syscalls"`
+}
+
+// unscopedAfxdpSyscalls are the remaining syscalls an AF_XDP pod needs:
+// loading the BPF program and maps, binding/sending/receiving on the
+// socket already scoped to AF_XDP above, and allocating the UMEM behind
+// it. They're allowed unconditionally rather than arg-scoped to AF_XDP
+// like socket/setsockopt/getsockopt, because seccomp can only compare
+// scalar argument values, not dereference the sockaddr/buffer pointers
+// bind/sendto/recvfrom take -- there's no argument here that identifies
+// "this is the AF_XDP socket" the way a socket() family or setsockopt()
+// level does.
+var unscopedAfxdpSyscalls = []string{
+	"bpf",
+	"bind",
+	"sendto",
+	"recvfrom",
+	"memfd_create",
+}
+
+// AllowList returns the full allow-list profile: SCMP_ACT_ERRNO by
+// default, with the afxdp syscalls explicitly allowed and, where the
+// profile format lets us express it, scoped to the AF_XDP family/level
+// rather than any socket. Runtimes that expect a complete,
+// self-contained profile should use this.
+func AllowList() Profile {
+	return Profile{
+		DefaultAction: "SCMP_ACT_ERRNO",
+		Syscalls: []Syscall{
+			{
+				Names:  []string{"socket"},
+				Action: "SCMP_ACT_ALLOW",
+				Args:   []Arg{{Index: 0, Value: afXDP, Op: "SCMP_CMP_EQ"}},
+			},
+			{
+				Names:  []string{"setsockopt", "getsockopt"},
+				Action: "SCMP_ACT_ALLOW",
+				Args:   []Arg{{Index: 1, Value: solXDP, Op: "SCMP_CMP_EQ"}},
+			},
+			{Names: unscopedAfxdpSyscalls, Action: "SCMP_ACT_ALLOW"},
+		},
+	}
+}
+
+// Install renders the allow-list profile and writes it to InstallPath so
+// the kubelet can serve it to the container runtime. Kubernetes'
+// localhost/ seccomp profile references replace the runtime default
+// wholesale rather than composing with it, so the installed profile
+// must always be complete and self-contained.
+func Install() error {
+	data, err := json.MarshalIndent(AllowList(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll("/var/lib/kubelet/seccomp", 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(InstallPath, data, 0644)
+}
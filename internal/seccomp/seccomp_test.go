@@ -0,0 +1,70 @@
+/*
+ * Copyright(c) 2022 Intel Corporation.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findSyscall(t *testing.T, profile Profile, name string) Syscall {
+	t.Helper()
+	for _, syscall := range profile.Syscalls {
+		for _, n := range syscall.Names {
+			if n == name {
+				return syscall
+			}
+		}
+	}
+	require.Failf(t, "syscall not found", "%s not present in profile", name)
+	return Syscall{}
+}
+
+func TestAllowListDeniesByDefault(t *testing.T) {
+	profile := AllowList()
+
+	assert.Equal(t, "SCMP_ACT_ERRNO", profile.DefaultAction)
+}
+
+func TestAllowListScopesSocketToAfXDP(t *testing.T) {
+	profile := AllowList()
+
+	socket := findSyscall(t, profile, "socket")
+	assert.Equal(t, "SCMP_ACT_ALLOW", socket.Action)
+	assert.Equal(t, []Arg{{Index: 0, Value: afXDP, Op: "SCMP_CMP_EQ"}}, socket.Args)
+}
+
+func TestAllowListScopesSockoptSyscallsToSolXDP(t *testing.T) {
+	profile := AllowList()
+
+	for _, name := range []string{"setsockopt", "getsockopt"} {
+		syscall := findSyscall(t, profile, name)
+		assert.Equal(t, "SCMP_ACT_ALLOW", syscall.Action)
+		assert.Equal(t, []Arg{{Index: 1, Value: solXDP, Op: "SCMP_CMP_EQ"}}, syscall.Args)
+	}
+}
+
+func TestAllowListAllowsTheRemainingAfxdpSyscallsUnscoped(t *testing.T) {
+	profile := AllowList()
+
+	for _, name := range unscopedAfxdpSyscalls {
+		syscall := findSyscall(t, profile, name)
+		assert.Equal(t, "SCMP_ACT_ALLOW", syscall.Action)
+		assert.Empty(t, syscall.Args)
+	}
+}